@@ -1,15 +1,12 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
 	"os/signal"
-	"runtime"
-	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -21,21 +18,37 @@ const (
 	defaultCount         = 10           // Default number of pings (0 for infinite)
 	defaultInterval      = 1            // Seconds between pings
 	highLatencyThreshold = 100.0        // ms; alert if above this
+	defaultMethod        = "exec"       // Default ping backend: "exec" or "native"
+	pingTimeout          = 5 * time.Second
 )
 
 type PingResult struct {
+	host      string
 	sequence  int
 	latency   float64
 	success   bool
 	timestamp time.Time
+	ttl       int
 }
 
 func main() {
-	target := flag.String("target", defaultTarget, "Target host to ping (e.g., google.com)")
+	target := flag.String("target", defaultTarget, "Target host(s) to ping, comma-separated (e.g., google.com,cloudflare.com)")
+	targetsFile := flag.String("targets-file", "", "File with one target host per line; combined with -target")
 	count := flag.Int("count", defaultCount, "Number of pings (0 for infinite)")
 	interval := flag.Int("interval", defaultInterval, "Interval between pings in seconds")
 	logFile := flag.String("log", "ping_log.txt", "File to log results")
+	format := flag.String("format", formatText, "Output format for the log file: \"text\", \"json\", \"csv\", or \"influx\"")
 	threshold := flag.Float64("threshold", highLatencyThreshold, "High latency threshold in ms")
+	method := flag.String("method", defaultMethod, "Ping backend to use: \"exec\" (shell out to OS ping) or \"native\" (craft ICMP packets directly)")
+	listen := flag.String("listen", "", "Address to serve Prometheus metrics on (e.g., :3000); disabled if empty")
+	window := flag.Int("window", 0, "In infinite mode, reflect only the last N samples in stats/graph (0 = unbounded)")
+	summaryInterval := flag.Int("summary-interval", 0, "Seconds between periodic summary lines (0 = disabled)")
+	deadline := flag.Int("deadline", 0, "Total wall-clock time cap in seconds, independent of -count (0 = no cap)")
+	iface := flag.String("interface", "", "Bind to this network interface (Unix only; mirrors ping -I)")
+	source := flag.String("source", "", "Bind to this source IP address (mirrors ping -S)")
+	size := flag.Int("size", 0, "ICMP payload size in bytes (mirrors ping -s/-l; 0 = default)")
+	ttl := flag.Int("ttl", 0, "IP TTL / hop limit to set on outgoing packets (mirrors ping -t/-i; 0 = OS default)")
+	tos := flag.Int("tos", 0, "IP TOS/DSCP byte to set on outgoing packets (mirrors ping -Q/-v; 0 = OS default)")
 
 	flag.Parse()
 
@@ -44,177 +57,282 @@ func main() {
 		os.Exit(1)
 	}
 
-	latencies := []float64{}
-	results := []PingResult{}
+	targets, err := resolveTargets(*target, *targetsFile)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	pingOpts := PingOptions{
+		Timeout:   pingTimeout,
+		Interface: *iface,
+		Source:    *source,
+		Size:      *size,
+		TTL:       *ttl,
+		TOS:       *tos,
+	}
+	if err := pingOpts.Validate(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	pinger := selectPinger(*method, pingOpts)
+
+	var metrics *metricsCollector
+	if *listen != "" {
+		metrics = newMetricsCollector()
+		serveMetrics(*listen)
+	}
 
-	fmt.Printf("Pinging %s every %d seconds", *target, *interval)
+	fmt.Printf("Pinging %s every %d seconds", strings.Join(targets, ", "), *interval)
 	if *count > 0 {
 		fmt.Printf(" (%d times)...\n", *count)
 	} else {
 		fmt.Printf(" (infinite - press Ctrl+C to stop)...\n")
 	}
 
-	// Handle Ctrl+C gracefully
+	// Handle Ctrl+C gracefully, stopping every target's goroutine.
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	stopChan := make(chan struct{})
+	var stopOnce sync.Once
+	stop := func(reason string) {
+		stopOnce.Do(func() {
+			fmt.Println(reason)
+			close(stopChan)
+		})
+	}
 
-	ticker := time.NewTicker(time.Duration(*interval) * time.Second)
-	defer ticker.Stop()
+	go func() {
+		<-sigChan
+		stop("\nReceived interrupt signal. Stopping...")
+	}()
+
+	if *deadline > 0 {
+		go func() {
+			<-time.After(time.Duration(*deadline) * time.Second)
+			stop(fmt.Sprintf("\nDeadline of %d seconds reached. Stopping...", *deadline))
+		}()
+	}
 
-	done := make(chan bool)
+	resultsCh := make(chan PingResult)
+	var wg sync.WaitGroup
+	for _, host := range targets {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			runTarget(pinger, host, *count, time.Duration(*interval)*time.Second, *threshold, stopChan, resultsCh)
+		}(host)
+	}
 
-	// Goroutine for continuous pinging
 	go func() {
-		defer close(done)
-		i := 0
-		for {
-			select {
-			case <-sigChan:
-				fmt.Println("\nReceived interrupt signal. Stopping...")
-				return
-			case <-ticker.C:
-				result := pingOnce(*target, i+1)
-				results = append(results, result)
+		wg.Wait()
+		close(resultsCh)
+	}()
 
-				if result.success {
-					latencies = append(latencies, result.latency)
-					fmt.Printf("Ping %d: %.2f ms", result.sequence, result.latency)
-					if result.latency > *threshold {
-						fmt.Printf(" [HIGH LATENCY ALERT: %.2f ms > %.0f ms]", result.latency, *threshold)
-					}
-					fmt.Println()
-				} else {
-					fmt.Printf("Ping %d: Request timeout or host unreachable\n", result.sequence)
+	var summaryTickerC <-chan time.Time
+	if *summaryInterval > 0 {
+		summaryTicker := time.NewTicker(time.Duration(*summaryInterval) * time.Second)
+		defer summaryTicker.Stop()
+		summaryTickerC = summaryTicker.C
+	}
+
+	resultsByHost := make(map[string][]PingResult)
+	rawWindows := make(map[string]*resultRingBuffer)
+	windows := make(map[string]*ringBuffer)
+	sentByHost := make(map[string]int)
+	recvByHost := make(map[string]int)
+
+	for draining := true; draining; {
+		select {
+		case result, ok := <-resultsCh:
+			if !ok {
+				draining = false
+				continue
+			}
+			sentByHost[result.host]++
+			if result.success {
+				recvByHost[result.host]++
+			}
+			if metrics != nil {
+				metrics.observe(result.host, result)
+			}
+			if *count == 0 && *window > 0 {
+				// Bound both the per-ping history and the derived
+				// latencies to the window, so memory stays bounded for
+				// the lifetime of an infinite run rather than growing
+				// without limit.
+				rw := rawWindows[result.host]
+				if rw == nil {
+					rw = newResultRingBuffer(*window)
+					rawWindows[result.host] = rw
 				}
+				rw.add(result)
 
-				i++
-				if *count > 0 && i >= *count {
-					return
+				if result.success {
+					w := windows[result.host]
+					if w == nil {
+						w = newRingBuffer(*window)
+						windows[result.host] = w
+					}
+					w.add(result.latency)
 				}
+			} else {
+				resultsByHost[result.host] = append(resultsByHost[result.host], result)
 			}
+		case <-summaryTickerC:
+			printSummaries(targets, resultsByHost, windows)
 		}
-	}()
+	}
 
-	// Wait for completion
-	<-done
+	// Display statistics and a graph per host. Packet counts come from the
+	// running sentByHost/recvByHost totals (which cover the whole run) so
+	// they never disagree with latencies, which may be a bounded -window
+	// subset.
+	for _, host := range targets {
+		if len(targets) > 1 {
+			fmt.Printf("\n=== %s ===\n", host)
+		}
 
-	// Display statistics
-	displayStats(results, latencies)
+		latencies := windowedLatencies(host, resultsByHost[host], windows)
+		displayStats(sentByHost[host], recvByHost[host], latencies)
 
-	// Generate and display graph
-	if len(latencies) > 0 {
-		fmt.Println("\nLatency Graph:")
-		graph := asciigraph.Plot(latencies,
-			asciigraph.Height(10),
-			asciigraph.Caption(fmt.Sprintf("Latency over time (ms) - Target: %s", *target)),
-			asciigraph.Width(60))
-		fmt.Println(graph)
-	} else {
-		fmt.Println("No successful pings to display graph.")
+		if len(latencies) > 0 {
+			fmt.Println("\nLatency Graph:")
+			graph := asciigraph.Plot(latencies,
+				asciigraph.Height(10),
+				asciigraph.Caption(fmt.Sprintf("Latency over time (ms) - Target: %s", host)),
+				asciigraph.Width(60))
+			fmt.Println(graph)
+		} else {
+			fmt.Println("No successful pings to display graph.")
+		}
 	}
 
-	// Log results
-	if err := logResults(*logFile, results, *target); err != nil {
+	// Log results. For windowed hosts, resultsByHost holds nothing (kept
+	// bounded above), so fall back to the bounded per-ping history.
+	loggedResults := make(map[string][]PingResult, len(targets))
+	for _, host := range targets {
+		loggedResults[host] = rawResultsFor(host, resultsByHost, rawWindows)
+	}
+	if err := writeResults(*logFile, *format, targets, loggedResults); err != nil {
 		fmt.Printf("Error logging results: %v\n", err)
 	} else {
 		fmt.Printf("Results logged to %s\n", *logFile)
 	}
 }
 
-// pingOnce runs a single ping and returns the result
-func pingOnce(target string, sequence int) PingResult {
-	result := PingResult{
-		sequence:  sequence,
-		timestamp: time.Now(),
-		success:   false,
+// rawResultsFor returns the per-ping history to use for host: the bounded
+// window if one is active, otherwise the full unbounded history.
+func rawResultsFor(host string, resultsByHost map[string][]PingResult, rawWindows map[string]*resultRingBuffer) []PingResult {
+	if rw, ok := rawWindows[host]; ok {
+		return rw.values()
 	}
+	return resultsByHost[host]
+}
 
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("ping", "-n", "1", target)
-	} else {
-		cmd = exec.Command("ping", "-c", "1", "-W", "5000", target) // 5 second timeout
-	}
+// runTarget repeatedly pings host on the given interval, sending each
+// result to resultsCh, until count pings have been sent (0 means run until
+// stopChan closes).
+func runTarget(pinger Pinger, host string, count int, interval time.Duration, threshold float64, stopChan <-chan struct{}, resultsCh chan<- PingResult) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	output, err := cmd.Output()
-	if err != nil {
-		return result
+	i := 0
+	for {
+		select {
+		case <-stopChan:
+			return
+		case <-ticker.C:
+			result, line := processPing(pinger, host, i+1, threshold)
+			resultsCh <- result
+			fmt.Println(line)
+
+			i++
+			if count > 0 && i >= count {
+				return
+			}
+		}
 	}
+}
 
-	// Parse output for latency
-	latency := parseLatency(string(output))
-	if latency >= 0 {
-		result.latency = latency
-		result.success = true
+// successfulLatencies extracts the latencies of successful results, in order.
+func successfulLatencies(results []PingResult) []float64 {
+	latencies := make([]float64, 0, len(results))
+	for _, result := range results {
+		if result.success {
+			latencies = append(latencies, result.latency)
+		}
 	}
+	return latencies
+}
 
-	return result
+// windowedLatencies returns the latencies to use for host's stats/graph: the
+// rolling window if one is active for host, otherwise every successful
+// result.
+func windowedLatencies(host string, results []PingResult, windows map[string]*ringBuffer) []float64 {
+	if w, ok := windows[host]; ok {
+		return w.values()
+	}
+	return successfulLatencies(results)
 }
 
-// parseLatency extracts latency from ping output
-func parseLatency(output string) float64 {
-	scanner := bufio.NewScanner(strings.NewReader(output))
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Handle different ping output formats
-		if runtime.GOOS == "windows" {
-			// Windows format: "time<1ms" or "time=10ms"
-			if strings.Contains(line, "time") && strings.Contains(line, "ms") {
-				if strings.Contains(line, "time<") {
-					parts := strings.Split(line, "time<")
-					if len(parts) > 1 {
-						msStr := strings.Split(parts[1], "ms")[0]
-						if ms, err := strconv.ParseFloat(msStr, 64); err == nil {
-							return ms - 0.5 // Assume <1ms means ~0.5ms
-						}
-					}
-				} else if strings.Contains(line, "time=") {
-					parts := strings.Split(line, "time=")
-					if len(parts) > 1 {
-						msStr := strings.Split(parts[1], "ms")[0]
-						if ms, err := strconv.ParseFloat(msStr, 64); err == nil {
-							return ms
-						}
-					}
-				}
-			}
-		} else {
-			// Unix/Linux format: "time=10.2 ms"
-			if strings.Contains(line, "time=") {
-				parts := strings.Split(line, "time=")
-				if len(parts) > 1 {
-					msStr := strings.Fields(parts[1])[0] // Get first field after "time="
-					if ms, err := strconv.ParseFloat(msStr, 64); err == nil {
-						return ms
-					}
-				}
-			}
+// printSummaries prints one periodic summary line per target, using
+// whatever results have accumulated so far.
+func printSummaries(targets []string, resultsByHost map[string][]PingResult, windows map[string]*ringBuffer) {
+	for _, host := range targets {
+		latencies := windowedLatencies(host, resultsByHost[host], windows)
+		if len(latencies) == 0 {
+			continue
 		}
+
+		stats := computeStats(latencies)
+		fmt.Printf("[summary] %s: avg=%.2fms stddev=%.2fms jitter=%.2fms p95=%.2fms (n=%d)\n",
+			host, stats.Avg, stats.StdDev, stats.Jitter, stats.P95, len(latencies))
 	}
+}
 
-	return -1 // No latency found
+// processPing runs a single ping through pinger and formats the status line
+// printed for it. Taking a Pinger rather than calling a backend directly
+// keeps this testable with a mock, independent of real network access.
+func processPing(pinger Pinger, target string, sequence int, threshold float64) (PingResult, string) {
+	result := pinger.Ping(target, sequence)
+
+	if !result.success {
+		return result, fmt.Sprintf("%s Ping %d: Request timeout or host unreachable", target, result.sequence)
+	}
+
+	line := fmt.Sprintf("%s Ping %d: %.2f ms", target, result.sequence, result.latency)
+	if result.ttl > 0 {
+		line += fmt.Sprintf(" (ttl=%d)", result.ttl)
+	}
+	if result.latency > threshold {
+		line += fmt.Sprintf(" [HIGH LATENCY ALERT: %.2f ms > %.0f ms]", result.latency, threshold)
+	}
+	return result, line
 }
 
-// displayStats shows ping statistics
-func displayStats(results []PingResult, latencies []float64) {
-	if len(results) == 0 {
+// displayStats shows ping statistics. sent and received are the host's
+// true lifetime totals; they're tracked independently of latencies, which
+// may only be a -window subset, so the two can't disagree.
+func displayStats(sent, received int, latencies []float64) {
+	if sent == 0 {
 		return
 	}
 
-	successful := len(latencies)
-	total := len(results)
-	packetLoss := float64(total-successful) / float64(total) * 100
+	packetLoss := float64(sent-received) / float64(sent) * 100
 
 	fmt.Println("\n--- Ping Statistics ---")
-	fmt.Printf("Packets sent: %d\n", total)
-	fmt.Printf("Packets received: %d\n", successful)
+	fmt.Printf("Packets sent: %d\n", sent)
+	fmt.Printf("Packets received: %d\n", received)
 	fmt.Printf("Packet loss: %.1f%%\n", packetLoss)
 
-	if successful > 0 {
-		min, max, avg := calculateStats(latencies)
-		fmt.Printf("Latency - Min: %.2f ms, Max: %.2f ms, Avg: %.2f ms\n", min, max, avg)
+	if len(latencies) > 0 {
+		stats := computeStats(latencies)
+		fmt.Printf("Latency - Min: %.2f ms, Max: %.2f ms, Avg: %.2f ms\n", stats.Min, stats.Max, stats.Avg)
+		fmt.Printf("Jitter: %.2f ms, StdDev: %.2f ms\n", stats.Jitter, stats.StdDev)
+		fmt.Printf("Percentiles - p50: %.2f ms, p90: %.2f ms, p95: %.2f ms, p99: %.2f ms\n",
+			stats.P50, stats.P90, stats.P95, stats.P99)
 	}
 }
 
@@ -241,34 +359,3 @@ func calculateStats(latencies []float64) (min, max, avg float64) {
 	avg = sum / float64(len(latencies))
 	return min, max, avg
 }
-
-// logResults writes results to a file
-func logResults(filename string, results []PingResult, target string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-	defer writer.Flush()
-
-	// Write header
-	writer.WriteString(fmt.Sprintf("Ping Log - Target: %s\n", target))
-	writer.WriteString(fmt.Sprintf("Generated: %s\n", time.Now().Format("2006-01-02 15:04:05")))
-	writer.WriteString("=====================================\n")
-
-	// Write results
-	for _, result := range results {
-		timestamp := result.timestamp.Format("15:04:05")
-		if result.success {
-			writer.WriteString(fmt.Sprintf("[%s] Ping %d: %.2f ms\n",
-				timestamp, result.sequence, result.latency))
-		} else {
-			writer.WriteString(fmt.Sprintf("[%s] Ping %d: FAILED\n",
-				timestamp, result.sequence))
-		}
-	}
-
-	return nil
-}
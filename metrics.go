@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsCollector exposes ping results as Prometheus metrics, so the tool
+// can run as a long-lived probe that gets scraped instead of only printing
+// a summary at exit.
+type metricsCollector struct {
+	rtt          *prometheus.GaugeVec
+	rttHistogram *prometheus.HistogramVec
+	transmitted  *prometheus.CounterVec
+	received     *prometheus.CounterVec
+	packetLoss   *prometheus.GaugeVec
+	up           *prometheus.GaugeVec
+	sentByTarget map[string]int
+	recvByTarget map[string]int
+}
+
+func newMetricsCollector() *metricsCollector {
+	c := &metricsCollector{
+		// Named "_last" rather than the bare "ping_rtt_seconds" a gauge
+		// would normally get: a Gauge and a Histogram sharing one fqName
+		// collide in the registry, and scrape configs/dashboards are far
+		// more likely to query the histogram as "ping_rtt_seconds_bucket"
+		// than the instantaneous gauge by its exact name.
+		rtt: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ping_rtt_seconds_last",
+			Help: "Round-trip time of the most recent ping, in seconds.",
+		}, []string{"target"}),
+		rttHistogram: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ping_rtt_seconds",
+			Help:    "Histogram of ping round-trip times, in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"target"}),
+		transmitted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ping_packets_transmitted_total",
+			Help: "Total number of ping requests sent.",
+		}, []string{"target"}),
+		received: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ping_packets_received_total",
+			Help: "Total number of ping replies received.",
+		}, []string{"target"}),
+		packetLoss: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ping_packet_loss_ratio",
+			Help: "Fraction of pings lost so far, between 0 and 1.",
+		}, []string{"target"}),
+		up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ping_up",
+			Help: "1 if the most recent ping succeeded, 0 otherwise.",
+		}, []string{"target"}),
+		sentByTarget: make(map[string]int),
+		recvByTarget: make(map[string]int),
+	}
+
+	prometheus.MustRegister(c.rtt, c.rttHistogram, c.transmitted, c.received, c.packetLoss, c.up)
+	return c
+}
+
+// observe records a PingResult for target into the collector's metrics.
+func (c *metricsCollector) observe(target string, result PingResult) {
+	c.sentByTarget[target]++
+	c.transmitted.WithLabelValues(target).Inc()
+
+	if result.success {
+		c.recvByTarget[target]++
+		c.received.WithLabelValues(target).Inc()
+		seconds := result.latency / 1000.0
+		c.rtt.WithLabelValues(target).Set(seconds)
+		c.rttHistogram.WithLabelValues(target).Observe(seconds)
+		c.up.WithLabelValues(target).Set(1)
+	} else {
+		c.up.WithLabelValues(target).Set(0)
+	}
+
+	sent := c.sentByTarget[target]
+	recv := c.recvByTarget[target]
+	loss := float64(sent-recv) / float64(sent)
+	c.packetLoss.WithLabelValues(target).Set(loss)
+}
+
+// serveMetrics starts an HTTP server exposing /metrics in Prometheus text
+// format on addr. It runs until the process exits.
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("metrics server stopped: %v", err)
+		}
+	}()
+
+	fmt.Printf("Serving Prometheus metrics on %s/metrics\n", addr)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatCSV    = "csv"
+	formatInflux = "influx"
+)
+
+// writeResults logs results for every target to filename in the requested
+// format, so the output can be consumed by downstream tooling (jq,
+// spreadsheets, Telegraf's exec input, ...) without post-processing.
+func writeResults(filename, format string, targets []string, resultsByHost map[string][]PingResult) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(filename, targets, resultsByHost)
+	case formatCSV:
+		return writeCSV(filename, targets, resultsByHost)
+	case formatInflux:
+		return writeInflux(filename, targets, resultsByHost)
+	default:
+		return writeText(filename, targets, resultsByHost)
+	}
+}
+
+// writeText writes results to a single log file, one section per host.
+func writeText(filename string, targets []string, resultsByHost map[string][]PingResult) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	defer writer.Flush()
+
+	writer.WriteString(fmt.Sprintf("Ping Log - Generated: %s\n", time.Now().Format("2006-01-02 15:04:05")))
+	writer.WriteString("=====================================\n")
+
+	for _, host := range targets {
+		writer.WriteString(fmt.Sprintf("\nTarget: %s\n", host))
+		for _, result := range resultsByHost[host] {
+			timestamp := result.timestamp.Format("15:04:05")
+			if result.success {
+				writer.WriteString(fmt.Sprintf("[%s] Ping %d: %.2f ms\n",
+					timestamp, result.sequence, result.latency))
+			} else {
+				writer.WriteString(fmt.Sprintf("[%s] Ping %d: FAILED\n",
+					timestamp, result.sequence))
+			}
+		}
+	}
+
+	return nil
+}
+
+type jsonPing struct {
+	Target    string    `json:"target"`
+	Sequence  int       `json:"sequence"`
+	Timestamp time.Time `json:"timestamp"`
+	RTTMs     float64   `json:"rtt_ms"`
+	Success   bool      `json:"success"`
+}
+
+type jsonSummary struct {
+	Target             string  `json:"target"`
+	PacketsTransmitted int     `json:"packets_transmitted"`
+	PacketsReceived    int     `json:"packets_received"`
+	PercentPacketLoss  float64 `json:"percent_packet_loss"`
+	MinRTTMs           float64 `json:"minimum_response_ms"`
+	MaxRTTMs           float64 `json:"maximum_response_ms"`
+	AvgRTTMs           float64 `json:"average_response_ms"`
+}
+
+type jsonOutput struct {
+	Pings   []jsonPing    `json:"pings"`
+	Summary []jsonSummary `json:"summary"`
+}
+
+// writeJSON emits one object per ping plus a final per-target summary block.
+func writeJSON(filename string, targets []string, resultsByHost map[string][]PingResult) error {
+	out := jsonOutput{}
+
+	for _, host := range targets {
+		results := resultsByHost[host]
+		for _, result := range results {
+			out.Pings = append(out.Pings, jsonPing{
+				Target:    host,
+				Sequence:  result.sequence,
+				Timestamp: result.timestamp,
+				RTTMs:     result.latency,
+				Success:   result.success,
+			})
+		}
+		out.Summary = append(out.Summary, summarize(host, results))
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	return os.WriteFile(filename, data, 0o644)
+}
+
+// writeCSV emits one row per ping: timestamp,sequence,target,rtt_ms,success.
+func writeCSV(filename string, targets []string, resultsByHost map[string][]PingResult) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"timestamp", "sequence", "target", "rtt_ms", "success"}); err != nil {
+		return err
+	}
+
+	for _, host := range targets {
+		for _, result := range resultsByHost[host] {
+			row := []string{
+				result.timestamp.Format(time.RFC3339),
+				strconv.Itoa(result.sequence),
+				host,
+				strconv.FormatFloat(result.latency, 'f', 2, 64),
+				strconv.FormatBool(result.success),
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return writer.Error()
+}
+
+// writeInflux emits one InfluxDB line protocol summary point per target.
+func writeInflux(filename string, targets []string, resultsByHost map[string][]PingResult) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	defer writer.Flush()
+
+	ts := time.Now().UnixNano()
+	for _, host := range targets {
+		s := summarize(host, resultsByHost[host])
+		line := fmt.Sprintf(
+			"ping,url=%s average_response_ms=%.2f,minimum_response_ms=%.2f,maximum_response_ms=%.2f,packets_transmitted=%di,packets_received=%di,percent_packet_loss=%.2f %d\n",
+			host, s.AvgRTTMs, s.MinRTTMs, s.MaxRTTMs, s.PacketsTransmitted, s.PacketsReceived, s.PercentPacketLoss, ts)
+		if _, err := writer.WriteString(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// summarize computes the packet loss and latency summary for one target's
+// results.
+func summarize(host string, results []PingResult) jsonSummary {
+	latencies := successfulLatencies(results)
+	total := len(results)
+	successful := len(latencies)
+
+	s := jsonSummary{
+		Target:             host,
+		PacketsTransmitted: total,
+		PacketsReceived:    successful,
+	}
+	if total > 0 {
+		s.PercentPacketLoss = float64(total-successful) / float64(total) * 100
+	}
+	if successful > 0 {
+		s.MinRTTMs, s.MaxRTTMs, s.AvgRTTMs = calculateStats(latencies)
+	}
+	return s
+}
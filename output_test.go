@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleResults() map[string][]PingResult {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	return map[string][]PingResult{
+		"example.com": {
+			{host: "example.com", sequence: 1, latency: 10, success: true, timestamp: now},
+			{host: "example.com", sequence: 2, success: false, timestamp: now.Add(time.Second)},
+		},
+	}
+}
+
+func TestWriteResultsCSV(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.csv")
+
+	if err := writeResults(path, formatCSV, []string{"example.com"}, sampleResults()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header + 2 rows, got %d lines: %q", len(lines), data)
+	}
+	if lines[0] != "timestamp,sequence,target,rtt_ms,success" {
+		t.Errorf("unexpected header: %q", lines[0])
+	}
+}
+
+func TestWriteResultsJSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.json")
+
+	if err := writeResults(path, formatJSON, []string{"example.com"}, sampleResults()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if !strings.Contains(string(data), `"pings"`) || !strings.Contains(string(data), `"summary"`) {
+		t.Errorf("expected pings and summary blocks, got %q", data)
+	}
+}
+
+func TestWriteResultsInflux(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.influx")
+
+	if err := writeResults(path, formatInflux, []string{"example.com"}, sampleResults()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "ping,url=example.com ") {
+		t.Errorf("unexpected influx line: %q", data)
+	}
+	if !strings.Contains(string(data), "percent_packet_loss=50.00") {
+		t.Errorf("expected 50%% packet loss, got %q", data)
+	}
+}
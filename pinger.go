@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// Pinger sends a single probe to target and reports the result. It lets us
+// swap the mechanism used to measure RTT (shelling out to the OS ping vs.
+// crafting ICMP packets ourselves) without touching the rest of the tool.
+type Pinger interface {
+	Ping(target string, sequence int) PingResult
+}
+
+// PingOptions holds the probe options common to both backends: how to wait,
+// what to bind to, and what to put on the wire. Zero values mean "use the
+// platform/OS default".
+type PingOptions struct {
+	Timeout   time.Duration
+	Interface string // Bind to this network interface (Unix only)
+	Source    string // Bind to this source IP address
+	Size      int    // Payload size in bytes
+	TTL       int    // IP TTL / hop limit
+	TOS       int    // IP TOS / DSCP byte (IPv4) or traffic class (IPv6)
+}
+
+// Validate checks option combinations that aren't enforceable by the flag
+// package itself, e.g. out-of-range byte values.
+func (o PingOptions) Validate() error {
+	if o.Size < 0 {
+		return fmt.Errorf("size must not be negative")
+	}
+	if o.TTL < 0 || o.TTL > 255 {
+		return fmt.Errorf("ttl must be between 0 and 255")
+	}
+	if o.TOS < 0 || o.TOS > 255 {
+		return fmt.Errorf("tos must be between 0 and 255")
+	}
+	if o.Interface != "" && o.Source != "" {
+		return fmt.Errorf("-interface and -source are mutually exclusive")
+	}
+	if o.Interface != "" && runtime.GOOS == "windows" {
+		return fmt.Errorf("-interface is not supported on windows; use -source instead")
+	}
+	return nil
+}
+
+// selectPinger builds the Pinger backend named by method ("exec" or
+// "native"). It falls back to the exec backend for unrecognized values so a
+// typo in -method doesn't make the tool refuse to run.
+func selectPinger(method string, opts PingOptions) Pinger {
+	if method == "native" {
+		return &NativePinger{Options: opts}
+	}
+	return &ExecPinger{Options: opts}
+}
+
+// ExecPinger shells out to the platform's ping binary and parses its output.
+// This is the original behavior, kept as the default backend since it needs
+// no special privileges.
+type ExecPinger struct {
+	Options PingOptions
+}
+
+func (p *ExecPinger) Ping(target string, sequence int) PingResult {
+	result := PingResult{
+		host:      target,
+		sequence:  sequence,
+		timestamp: time.Now(),
+		success:   false,
+	}
+
+	cmd := exec.Command("ping", execPingArgs(p.Options, target)...)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return result
+	}
+
+	latency := parseLatency(string(output))
+	if latency >= 0 {
+		result.latency = latency
+		result.success = true
+	}
+
+	return result
+}
+
+// execPingArgs builds the OS ping command line for a single probe,
+// mirroring standard ping flags for source/interface/size/ttl/tos.
+func execPingArgs(opts PingOptions, target string) []string {
+	var args []string
+
+	if runtime.GOOS == "windows" {
+		args = append(args, "-n", "1")
+		if opts.Source != "" {
+			args = append(args, "-S", opts.Source)
+		}
+		if opts.Size > 0 {
+			args = append(args, "-l", strconv.Itoa(opts.Size))
+		}
+		if opts.TTL > 0 {
+			args = append(args, "-i", strconv.Itoa(opts.TTL))
+		}
+		if opts.TOS > 0 {
+			args = append(args, "-v", strconv.Itoa(opts.TOS))
+		}
+	} else {
+		timeoutMs := int(opts.Timeout / time.Millisecond)
+		args = append(args, "-c", "1", "-W", strconv.Itoa(timeoutMs))
+		if opts.Interface != "" {
+			args = append(args, "-I", opts.Interface)
+		}
+		if opts.Source != "" {
+			args = append(args, "-S", opts.Source)
+		}
+		if opts.Size > 0 {
+			args = append(args, "-s", strconv.Itoa(opts.Size))
+		}
+		if opts.TTL > 0 {
+			args = append(args, "-t", strconv.Itoa(opts.TTL))
+		}
+		if opts.TOS > 0 {
+			args = append(args, "-Q", strconv.Itoa(opts.TOS))
+		}
+	}
+
+	return append(args, target)
+}
+
+// parseLatency extracts latency from ping output
+func parseLatency(output string) float64 {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Handle different ping output formats
+		if runtime.GOOS == "windows" {
+			// Windows format: "time<1ms" or "time=10ms"
+			if strings.Contains(line, "time") && strings.Contains(line, "ms") {
+				if strings.Contains(line, "time<") {
+					parts := strings.Split(line, "time<")
+					if len(parts) > 1 {
+						msStr := strings.Split(parts[1], "ms")[0]
+						if ms, err := strconv.ParseFloat(msStr, 64); err == nil {
+							return ms - 0.5 // Assume <1ms means ~0.5ms
+						}
+					}
+				} else if strings.Contains(line, "time=") {
+					parts := strings.Split(line, "time=")
+					if len(parts) > 1 {
+						msStr := strings.Split(parts[1], "ms")[0]
+						if ms, err := strconv.ParseFloat(msStr, 64); err == nil {
+							return ms
+						}
+					}
+				}
+			}
+		} else {
+			// Unix/Linux format: "time=10.2 ms"
+			if strings.Contains(line, "time=") {
+				parts := strings.Split(line, "time=")
+				if len(parts) > 1 {
+					msStr := strings.Fields(parts[1])[0] // Get first field after "time="
+					if ms, err := strconv.ParseFloat(msStr, 64); err == nil {
+						return ms
+					}
+				}
+			}
+		}
+	}
+
+	return -1 // No latency found
+}
+
+// NativePinger crafts ICMP Echo Request packets directly instead of
+// shelling out, so behavior doesn't depend on the OS ping binary's output
+// format (which varies across locales and between Unix and Windows).
+type NativePinger struct {
+	Options PingOptions
+}
+
+const nativePingerPayload = "ping-analyzer"
+
+func (p *NativePinger) Ping(target string, sequence int) PingResult {
+	result := PingResult{
+		host:      target,
+		sequence:  sequence,
+		timestamp: time.Now(),
+		success:   false,
+	}
+
+	dst, err := net.ResolveIPAddr("ip", target)
+	if err != nil {
+		return result
+	}
+
+	id := os.Getpid() & 0xffff
+
+	var rtt time.Duration
+	var ttl int
+	if dst.IP.To4() != nil {
+		rtt, ttl, err = p.pingIPv4(dst, id, sequence)
+	} else {
+		rtt, ttl, err = p.pingIPv6(dst, id, sequence)
+	}
+	if err != nil {
+		return result
+	}
+
+	result.latency = float64(rtt.Microseconds()) / 1000.0
+	result.ttl = ttl
+	result.success = true
+	return result
+}
+
+// payload returns the Echo body of the configured size, falling back to the
+// default payload when no size was requested.
+func (p *NativePinger) payload() []byte {
+	if p.Options.Size <= 0 {
+		return []byte(nativePingerPayload)
+	}
+	return make([]byte, p.Options.Size)
+}
+
+// sourceAddr resolves the local address to bind to, from -source directly
+// or by looking up -interface's address.
+func (p *NativePinger) sourceAddr(v6 bool) (string, error) {
+	if p.Options.Source != "" {
+		return p.Options.Source, nil
+	}
+	if p.Options.Interface == "" {
+		if v6 {
+			return "::", nil
+		}
+		return "0.0.0.0", nil
+	}
+
+	iface, err := net.InterfaceByName(p.Options.Interface)
+	if err != nil {
+		return "", fmt.Errorf("looking up interface %s: %w", p.Options.Interface, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return "", err
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		isV4 := ipNet.IP.To4() != nil
+		if isV4 != v6 {
+			return ipNet.IP.String(), nil
+		}
+	}
+	return "", fmt.Errorf("interface %s has no address for this IP version", p.Options.Interface)
+}
+
+func (p *NativePinger) pingIPv4(dst *net.IPAddr, id, sequence int) (time.Duration, int, error) {
+	source, err := p.sourceAddr(false)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	conn, err := icmp.ListenPacket("udp4", source)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer conn.Close()
+
+	// udp4 is the unprivileged "ping socket" variant: the kernel owns the
+	// ICMP ID (it rewrites it to the socket's bound local port) and WriteTo
+	// requires a *net.UDPAddr rather than the *net.IPAddr we resolved the
+	// target to.
+	localID := id
+	if udpAddr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		localID = udpAddr.Port & 0xffff
+	}
+
+	msg := icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Code: 0,
+		Body: &icmp.Echo{
+			ID:   localID,
+			Seq:  sequence,
+			Data: p.payload(),
+		},
+	}
+	data, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pc := conn.IPv4PacketConn()
+	// Not fatal if unsupported: some platforms don't allow reading TTL on an
+	// unprivileged socket. We just won't report it.
+	_ = pc.SetControlMessage(ipv4.FlagTTL, true)
+	if p.Options.TTL > 0 {
+		_ = pc.SetTTL(p.Options.TTL)
+	}
+	if p.Options.TOS > 0 {
+		_ = pc.SetTOS(p.Options.TOS)
+	}
+
+	start := time.Now()
+	if _, err := conn.WriteTo(data, &net.UDPAddr{IP: dst.IP, Zone: dst.Zone}); err != nil {
+		return 0, 0, err
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(p.Options.Timeout)); err != nil {
+		return 0, 0, err
+	}
+
+	reply := make([]byte, 1500)
+	n, cm, _, err := pc.ReadFrom(reply)
+	if err != nil {
+		return 0, 0, err
+	}
+	rtt := time.Since(start)
+
+	parsed, err := icmp.ParseMessage(1, reply[:n])
+	if err != nil {
+		return 0, 0, err
+	}
+	// The kernel already demuxed this reply to our socket by ID/port, so we
+	// only need to confirm it's an echo reply for the sequence we sent.
+	echo, ok := parsed.Body.(*icmp.Echo)
+	if !ok || echo.Seq != sequence {
+		return 0, 0, fmt.Errorf("unexpected reply")
+	}
+
+	ttl := 0
+	if cm != nil {
+		ttl = cm.TTL
+	}
+	return rtt, ttl, nil
+}
+
+func (p *NativePinger) pingIPv6(dst *net.IPAddr, id, sequence int) (time.Duration, int, error) {
+	source, err := p.sourceAddr(true)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	conn, err := icmp.ListenPacket("udp6", source)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer conn.Close()
+
+	// See pingIPv4: udp6 is also the unprivileged variant, so the kernel
+	// owns the ID and WriteTo wants a *net.UDPAddr.
+	localID := id
+	if udpAddr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		localID = udpAddr.Port & 0xffff
+	}
+
+	msg := icmp.Message{
+		Type: ipv6.ICMPTypeEchoRequest,
+		Code: 0,
+		Body: &icmp.Echo{
+			ID:   localID,
+			Seq:  sequence,
+			Data: p.payload(),
+		},
+	}
+	data, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pc := conn.IPv6PacketConn()
+	// Not fatal if unsupported: see pingIPv4.
+	_ = pc.SetControlMessage(ipv6.FlagHopLimit, true)
+	if p.Options.TTL > 0 {
+		_ = pc.SetHopLimit(p.Options.TTL)
+	}
+	if p.Options.TOS > 0 {
+		_ = pc.SetTrafficClass(p.Options.TOS)
+	}
+
+	start := time.Now()
+	if _, err := conn.WriteTo(data, &net.UDPAddr{IP: dst.IP, Zone: dst.Zone}); err != nil {
+		return 0, 0, err
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(p.Options.Timeout)); err != nil {
+		return 0, 0, err
+	}
+
+	reply := make([]byte, 1500)
+	n, cm, _, err := pc.ReadFrom(reply)
+	if err != nil {
+		return 0, 0, err
+	}
+	rtt := time.Since(start)
+
+	parsed, err := icmp.ParseMessage(58, reply[:n])
+	if err != nil {
+		return 0, 0, err
+	}
+	// The kernel already demuxed this reply to our socket by ID/port, so we
+	// only need to confirm it's an echo reply for the sequence we sent.
+	echo, ok := parsed.Body.(*icmp.Echo)
+	if !ok || echo.Seq != sequence {
+		return 0, 0, fmt.Errorf("unexpected reply")
+	}
+
+	ttl := 0
+	if cm != nil {
+		ttl = cm.HopLimit
+	}
+	return rtt, ttl, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+// ringBuffer holds the last N float64 samples, overwriting the oldest once
+// full, so a rolling window's memory stays bounded regardless of how long a
+// run lasts.
+type ringBuffer struct {
+	data []float64
+	next int
+	full bool
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{data: make([]float64, size)}
+}
+
+func (r *ringBuffer) add(v float64) {
+	r.data[r.next] = v
+	r.next = (r.next + 1) % len(r.data)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// values returns the buffered samples in the order they were added.
+func (r *ringBuffer) values() []float64 {
+	if !r.full {
+		return append([]float64(nil), r.data[:r.next]...)
+	}
+
+	out := make([]float64, 0, len(r.data))
+	out = append(out, r.data[r.next:]...)
+	out = append(out, r.data[:r.next]...)
+	return out
+}
+
+// resultRingBuffer holds the last N PingResults, overwriting the oldest
+// once full. It mirrors ringBuffer but retains whole results rather than
+// bare latencies, so a windowed run's per-ping history (used for the log
+// file and periodic summaries) stays bounded too, not just its stats.
+type resultRingBuffer struct {
+	data []PingResult
+	next int
+	full bool
+}
+
+func newResultRingBuffer(size int) *resultRingBuffer {
+	return &resultRingBuffer{data: make([]PingResult, size)}
+}
+
+func (r *resultRingBuffer) add(v PingResult) {
+	r.data[r.next] = v
+	r.next = (r.next + 1) % len(r.data)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// values returns the buffered results in the order they were added.
+func (r *resultRingBuffer) values() []PingResult {
+	if !r.full {
+		return append([]PingResult(nil), r.data[:r.next]...)
+	}
+
+	out := make([]PingResult, 0, len(r.data))
+	out = append(out, r.data[r.next:]...)
+	out = append(out, r.data[:r.next]...)
+	return out
+}
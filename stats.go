@@ -0,0 +1,92 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// Stats summarizes a set of latencies beyond simple min/max/avg, giving the
+// same signal-quality picture (jitter, stddev, percentiles) that monitoring
+// plugins typically report.
+type Stats struct {
+	Min, Max, Avg      float64
+	StdDev             float64
+	Jitter             float64
+	P50, P90, P95, P99 float64
+}
+
+// computeStats builds a Stats from a slice of latencies, in the order they
+// were recorded (order matters for jitter, which compares consecutive
+// samples).
+func computeStats(latencies []float64) Stats {
+	min, max, avg := calculateStats(latencies)
+
+	sorted := append([]float64(nil), latencies...)
+	sort.Float64s(sorted)
+
+	return Stats{
+		Min:    min,
+		Max:    max,
+		Avg:    avg,
+		StdDev: stdDev(latencies),
+		Jitter: meanAbsJitter(latencies),
+		P50:    percentile(sorted, 50),
+		P90:    percentile(sorted, 90),
+		P95:    percentile(sorted, 95),
+		P99:    percentile(sorted, 99),
+	}
+}
+
+// stdDev computes the sample standard deviation using Welford's online
+// algorithm, which avoids the numerical instability of the naive
+// sum-of-squares approach.
+func stdDev(latencies []float64) float64 {
+	if len(latencies) < 2 {
+		return 0
+	}
+
+	var mean, m2 float64
+	for n, x := range latencies {
+		delta := x - mean
+		mean += delta / float64(n+1)
+		m2 += delta * (x - mean)
+	}
+
+	return math.Sqrt(m2 / float64(len(latencies)-1))
+}
+
+// meanAbsJitter is the average absolute difference between consecutive
+// latencies.
+func meanAbsJitter(latencies []float64) float64 {
+	if len(latencies) < 2 {
+		return 0
+	}
+
+	sum := 0.0
+	for i := 1; i < len(latencies); i++ {
+		diff := latencies[i] - latencies[i-1]
+		if diff < 0 {
+			diff = -diff
+		}
+		sum += diff
+	}
+
+	return sum / float64(len(latencies)-1)
+}
+
+// percentile returns the pth percentile (0-100) of sorted, which must
+// already be sorted in ascending order.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
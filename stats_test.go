@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.001
+}
+
+func TestComputeStats(t *testing.T) {
+	latencies := []float64{10, 20, 30, 40, 50}
+
+	stats := computeStats(latencies)
+
+	if !approxEqual(stats.Min, 10) || !approxEqual(stats.Max, 50) || !approxEqual(stats.Avg, 30) {
+		t.Errorf("unexpected min/max/avg: %+v", stats)
+	}
+	if !approxEqual(stats.StdDev, 15.8113) {
+		t.Errorf("unexpected stddev: got %f", stats.StdDev)
+	}
+	if !approxEqual(stats.Jitter, 10) {
+		t.Errorf("unexpected jitter: got %f", stats.Jitter)
+	}
+	if !approxEqual(stats.P50, 30) {
+		t.Errorf("unexpected p50: got %f", stats.P50)
+	}
+	if !approxEqual(stats.P99, 50) {
+		t.Errorf("unexpected p99: got %f", stats.P99)
+	}
+}
+
+func TestComputeStatsSingleSample(t *testing.T) {
+	stats := computeStats([]float64{42})
+
+	if stats.StdDev != 0 || stats.Jitter != 0 {
+		t.Errorf("expected zero stddev/jitter for a single sample, got %+v", stats)
+	}
+	if !approxEqual(stats.P50, 42) {
+		t.Errorf("expected p50 == 42, got %f", stats.P50)
+	}
+}
+
+func TestRingBuffer(t *testing.T) {
+	r := newRingBuffer(3)
+
+	r.add(1)
+	r.add(2)
+	if got := r.values(); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("unexpected values before full: %v", got)
+	}
+
+	r.add(3)
+	r.add(4) // overwrites the 1
+
+	got := r.values()
+	want := []float64{2, 3, 4}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected length: %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("unexpected values after wraparound: %v", got)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// resolveTargets builds the final list of hosts to ping from the
+// comma-separated -target flag and/or the -targets-file flag. Entries from
+// both sources are combined, trimmed, and deduplicated while preserving
+// first-seen order.
+func resolveTargets(targetFlag, targetsFile string) ([]string, error) {
+	var raw []string
+
+	for _, host := range strings.Split(targetFlag, ",") {
+		raw = append(raw, host)
+	}
+
+	if targetsFile != "" {
+		fileTargets, err := readTargetsFile(targetsFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading targets file: %w", err)
+		}
+		raw = append(raw, fileTargets...)
+	}
+
+	seen := make(map[string]bool)
+	var targets []string
+	for _, host := range raw {
+		host = strings.TrimSpace(host)
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		targets = append(targets, host)
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no targets specified")
+	}
+
+	return targets, nil
+}
+
+// readTargetsFile reads one host per line, ignoring blank lines and lines
+// starting with "#".
+func readTargetsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var hosts []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hosts = append(hosts, line)
+	}
+
+	return hosts, scanner.Err()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveTargetsFromFlag(t *testing.T) {
+	targets, err := resolveTargets("google.com, cloudflare.com ,google.com", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"google.com", "cloudflare.com"}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("got %v, want %v", targets, want)
+	}
+}
+
+func TestResolveTargetsFromFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "targets.txt")
+	contents := "example.com\n# a comment\n\n  example.org  \n"
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write targets file: %v", err)
+	}
+
+	targets, err := resolveTargets("example.com", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"example.com", "example.org"}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("got %v, want %v", targets, want)
+	}
+}
+
+func TestResolveTargetsEmpty(t *testing.T) {
+	if _, err := resolveTargets("", ""); err == nil {
+		t.Error("expected an error when no targets are specified")
+	}
+}